@@ -0,0 +1,85 @@
+package gtasks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Result carries the outcome of a single task execution: the value
+// returned by a value-producing function registered through AddKeyed
+// (nil for the legacy chan bool and error-only NewTaskContext paths)
+// alongside any error it returned.
+type Result struct {
+	Value interface{}
+	Err   error
+}
+
+// keyedCall tracks an AddKeyed execution that is currently in flight, so
+// concurrent RunKeyed calls for the same key can coalesce onto it
+// instead of starting a redundant run.
+type keyedCall struct {
+	wg  sync.WaitGroup
+	res Result
+}
+
+// AddKeyed registers f under key for use with RunKeyed. Unlike Add/
+// AddContext, f returns a value alongside its error so that callers
+// coalescing onto an in-flight run (see RunKeyed) all receive the same
+// result. Keyed tasks are kept out of the plain Add/AddContext task map,
+// so a plain Runner.Run() never starts one directly and races with
+// RunKeyed's own coalescing - RunKeyed is the only way to trigger them.
+func (r *Runner) AddKeyed(key string, f func(ctx context.Context) (interface{}, error)) *Task {
+	t := &Task{
+		name:      key,
+		valueFunc: f,
+		listeners: make([]chan Result, 0),
+	}
+	t.init()
+	r.mu.Lock()
+	if r.keyedTasks == nil {
+		r.keyedTasks = make(map[string]*Task)
+	}
+	r.keyedTasks[key] = t
+	r.attach(t)
+	if r.keyed == nil {
+		r.keyed = make(map[string]*keyedCall)
+	}
+	r.mu.Unlock()
+	return t
+}
+
+// RunKeyed triggers the task registered under key with AddKeyed. If an
+// execution for key is already in flight, the caller coalesces onto it
+// and blocks for the same Result instead of starting a second, redundant
+// run - the singleflight pattern. The task's Subscribe()rs still
+// receive one Result per actual execution, not per RunKeyed call.
+func (r *Runner) RunKeyed(key string) Result {
+	r.mu.Lock()
+	t, ok := r.keyedTasks[key]
+	if !ok {
+		r.mu.Unlock()
+		return Result{Err: fmt.Errorf("gtasks: no keyed task registered for %q", key)}
+	}
+	if call, inflight := r.keyed[key]; inflight {
+		r.mu.Unlock()
+		call.wg.Wait()
+		return call.res
+	}
+
+	call := &keyedCall{}
+	call.wg.Add(1)
+	r.keyed[key] = call
+	r.mu.Unlock()
+
+	res := t.invoke()
+
+	r.mu.Lock()
+	delete(r.keyed, key)
+	r.mu.Unlock()
+
+	call.res = res
+	call.wg.Done()
+	t.publish(res)
+	return res
+}
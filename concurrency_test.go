@@ -0,0 +1,78 @@
+package gtasks
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunnerRunKeyedCoalesces(t *testing.T) {
+	var calls int32
+
+	r := New()
+	r.AddKeyed("build", func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(time.Millisecond * 50)
+		return "done", nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([]Result, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = r.RunKeyed("build")
+		}(i)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected f to run once, ran %d times", got)
+	}
+
+	for _, res := range results {
+		if res.Value != "done" || res.Err != nil {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+	}
+}
+
+func TestRunnerRunKeyedUnknownKey(t *testing.T) {
+	r := New()
+	res := r.RunKeyed("missing")
+	if res.Err == nil {
+		t.Fatal("expected an error for an unregistered key")
+	}
+}
+
+func TestRunnerSetMaxConcurrent(t *testing.T) {
+	var running, maxRunning int32
+
+	r := New()
+	r.SetMaxConcurrent(2)
+
+	for i := 0; i < 5; i++ {
+		i := i
+		r.Add(string(rune('a'+i)), func(chan bool) {
+			cur := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&maxRunning)
+				if cur <= old || atomic.CompareAndSwapInt32(&maxRunning, old, cur) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond * 30)
+			atomic.AddInt32(&running, -1)
+		})
+	}
+
+	r.Run()
+	time.Sleep(time.Millisecond * 200)
+
+	if got := atomic.LoadInt32(&maxRunning); got > 2 {
+		t.Fatalf("expected at most 2 tasks running concurrently, saw %d", got)
+	}
+}
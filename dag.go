@@ -0,0 +1,182 @@
+package gtasks
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrDependencyCycle is returned by RunDAG, wrapped with the task name
+// where the cycle was found, when the graph built with DependsOn isn't
+// a DAG.
+var ErrDependencyCycle = errors.New("gtasks: dependency graph has a cycle")
+
+// ErrUnknownDependency is returned by RunDAG, wrapped with the unknown
+// name, when DependsOn recorded a dependency that doesn't match any
+// task registered with Add/AddContext.
+var ErrUnknownDependency = errors.New("gtasks: unknown dependency")
+
+// DependsOn records that task may only start once every task named in
+// deps has completed successfully. Edges can be added in any order;
+// the graph is only validated for cycles when RunDAG is called.
+func (r *Runner) DependsOn(task string, deps ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.edges == nil {
+		r.edges = make(map[string][]string)
+	}
+	r.edges[task] = append(r.edges[task], deps...)
+}
+
+// RunDAG validates that the dependency graph built with DependsOn is
+// acyclic and then runs every registered task exactly once, each
+// starting only after all of its dependencies have finished without
+// error - fanning in via a merge goroutine that waits on every
+// dependency's Subscribe() channel before firing the dependent's After.
+// A dependency that errors blocks its dependents from ever starting.
+// RunDAG returns ErrDependencyCycle if the graph isn't a DAG, or
+// ErrUnknownDependency if DependsOn recorded a dependency name that was
+// never registered with Add/AddContext. Edges recorded for a dependent
+// task name that was never registered are ignored.
+func (r *Runner) RunDAG() error {
+	r.mu.Lock()
+	order, err := r.topoOrder()
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	tasks := r.tasks
+	edges := r.edges
+	r.mu.Unlock()
+
+	// Wire up every task's Once/After/Subscribe before starting any of
+	// them: a dependency with no deps of its own starts executing the
+	// moment its goroutine is scheduled, and would otherwise be able to
+	// publish() to its listeners slice before a later loop iteration's
+	// Subscribe() call on that same task finished appending to it.
+	for _, name := range order {
+		task, ok := tasks[name]
+		if !ok {
+			continue
+		}
+		task.Once()
+
+		deps := edges[name]
+		if len(deps) > 0 {
+			subs := make([]chan Result, 0, len(deps))
+			for _, dep := range deps {
+				if dt, ok := tasks[dep]; ok {
+					subs = append(subs, dt.Subscribe())
+				}
+			}
+			merged := make(chan interface{})
+			task.After(merged)
+			go fanIn(subs, merged)
+		}
+	}
+
+	for _, name := range order {
+		task, ok := tasks[name]
+		if !ok {
+			continue
+		}
+		if len(edges[name]) == 0 {
+			task.Now()
+		}
+		go task.Run()
+	}
+	return nil
+}
+
+// fanIn waits for a successful Result on every dep channel before
+// sending a single trigger on merged, so a task only starts once all of
+// its dependencies have finished without error.
+func fanIn(subs []chan Result, merged chan interface{}) {
+	for _, s := range subs {
+		if res := <-s; res.Err != nil {
+			return
+		}
+	}
+	merged <- struct{}{}
+}
+
+// topoOrder returns the registered tasks in dependency order (a
+// dependency always comes before anything that depends on it) using DFS
+// coloring to also detect cycles. Callers must hold r.mu.
+func (r *Runner) topoOrder() ([]string, error) {
+	const (
+		white = iota
+		gray
+		black
+	)
+	color := make(map[string]int, len(r.tasks))
+	var order []string
+
+	names := make([]string, 0, len(r.tasks))
+	for name := range r.tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names) // deterministic order, so Explain/RunDAG behave reproducibly
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch color[name] {
+		case black:
+			return nil
+		case gray:
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, name)
+		}
+		color[name] = gray
+
+		deps := append([]string(nil), r.edges[name]...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if _, ok := r.tasks[dep]; !ok {
+				return fmt.Errorf("%w: %q (depended on by %q)", ErrUnknownDependency, dep, name)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		color[name] = black
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// Explain returns the dependency graph recorded via DependsOn in
+// Graphviz DOT format, for visualizing or debugging a pipeline before
+// running it.
+func (r *Runner) Explain() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var b strings.Builder
+	b.WriteString("digraph gtasks {\n")
+
+	names := make([]string, 0, len(r.edges))
+	for name := range r.edges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		deps := append([]string(nil), r.edges[name]...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			fmt.Fprintf(&b, "\t%q -> %q;\n", dep, name)
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
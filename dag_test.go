@@ -0,0 +1,105 @@
+package gtasks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunnerRunDAGOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func(chan bool) {
+		return func(chan bool) {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}
+	}
+
+	r := New()
+	r.Add("a", record("a"))
+	r.Add("b", record("b"))
+	r.Add("c", record("c"))
+
+	r.DependsOn("b", "a")
+	r.DependsOn("c", "b")
+
+	if err := r.RunDAG(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(time.Millisecond * 100)
+
+	mu.Lock()
+	got := append([]string(nil), order...)
+	mu.Unlock()
+
+	if strings.Join(got, ",") != "a,b,c" {
+		t.Fatalf("expected a,b,c in order, got %v", got)
+	}
+}
+
+func TestRunnerRunDAGCycle(t *testing.T) {
+	r := New()
+	r.Add("a", func(chan bool) {})
+	r.Add("b", func(chan bool) {})
+
+	r.DependsOn("a", "b")
+	r.DependsOn("b", "a")
+
+	if err := r.RunDAG(); !errors.Is(err, ErrDependencyCycle) {
+		t.Fatalf("expected ErrDependencyCycle, got %v", err)
+	}
+}
+
+func TestRunnerRunDAGSkipsAfterFailedDependency(t *testing.T) {
+	var ran int32
+
+	r := New()
+	r.AddContext("a", func(ctx context.Context) error {
+		return errors.New("boom")
+	})
+	r.Add("b", func(chan bool) {
+		ran = 1
+	})
+
+	r.DependsOn("b", "a")
+
+	if err := r.RunDAG(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(time.Millisecond * 100)
+
+	if ran != 0 {
+		t.Fatal("b should not have run since its dependency failed")
+	}
+}
+
+func TestRunnerRunDAGUnknownDependency(t *testing.T) {
+	r := New()
+	r.Add("a", func(chan bool) {})
+	r.Add("b", func(chan bool) {})
+
+	r.DependsOn("b", "a", "typo-name")
+
+	if err := r.RunDAG(); !errors.Is(err, ErrUnknownDependency) {
+		t.Fatalf("expected ErrUnknownDependency, got %v", err)
+	}
+}
+
+func TestRunnerExplain(t *testing.T) {
+	r := New()
+	r.Add("a", func(chan bool) {})
+	r.Add("b", func(chan bool) {})
+	r.DependsOn("b", "a")
+
+	dot := r.Explain()
+	if !strings.Contains(dot, `"a" -> "b";`) {
+		t.Fatalf("expected DOT output to contain the a->b edge, got:\n%s", dot)
+	}
+}
@@ -0,0 +1,151 @@
+package gtasks
+
+import (
+	"expvar"
+	"time"
+)
+
+// TaskResult is one historical record of a single task execution: when
+// it started and ended, the error it returned (if any), a recovered
+// panic value (if it panicked instead of returning), and how many
+// consecutive attempts it represents. Attempts only accumulates across
+// a WithBackoff retry sequence, resetting to 1 on success; without
+// WithBackoff configured every execution is independent (e.g. separate
+// Every/Cron firings) and always reports 1.
+type TaskResult struct {
+	Start    time.Time
+	End      time.Time
+	Err      error
+	Panic    interface{}
+	Attempts int
+}
+
+// Observer receives lifecycle events for a task, for building
+// dashboards or health endpoints on top of gtasks without hooking into
+// the normal trigger/Subscribe flow. Implementations must not block,
+// since they run synchronously on the goroutine executing the task
+// (OnCancel: on the goroutine calling Cancel, with the task's lock held).
+type Observer interface {
+	OnStart(name string)
+	OnFinish(name string, res TaskResult)
+	OnCancel(name string)
+	OnPanic(name string, p interface{})
+}
+
+// Observe registers o to receive this task's lifecycle events.
+func (t *Task) Observe(o Observer) *Task {
+	t.observers = append(t.observers, o)
+	return t
+}
+
+// LastResult returns the most recently recorded TaskResult for this
+// task, or nil if it hasn't run yet.
+func (t *Task) LastResult() *TaskResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.history) == 0 {
+		return nil
+	}
+	last := t.history[len(t.history)-1]
+	return &last
+}
+
+// History returns the n most recent TaskResults, oldest first. A
+// non-positive n, or one larger than the number of recorded runs,
+// returns the full history.
+func (t *Task) History(n int) []TaskResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n <= 0 || n > len(t.history) {
+		n = len(t.history)
+	}
+	out := make([]TaskResult, n)
+	copy(out, t.history[len(t.history)-n:])
+	return out
+}
+
+// Observe registers o on every task the Runner currently manages
+// (including keyed ones registered via AddKeyed), and on every task
+// added afterwards.
+func (r *Runner) Observe(o Observer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observers = append(r.observers, o)
+	for _, t := range r.tasks {
+		t.Observe(o)
+	}
+	for _, t := range r.keyedTasks {
+		t.Observe(o)
+	}
+}
+
+// attach wires every Observer registered on the Runner onto t. Callers
+// must hold r.mu.
+func (r *Runner) attach(t *Task) {
+	for _, o := range r.observers {
+		t.Observe(o)
+	}
+}
+
+// Stats summarizes one task's execution history for dashboards or
+// health endpoints.
+type Stats struct {
+	Runs   int
+	Errors int
+	Panics int
+	Last   *TaskResult
+}
+
+// Stats returns a Stats snapshot for every task the Runner manages
+// (including keyed ones registered via AddKeyed), keyed by name.
+func (r *Runner) Stats() map[string]Stats {
+	r.mu.RLock()
+	tasks := make(map[string]*Task, len(r.tasks)+len(r.keyedTasks))
+	for name, t := range r.tasks {
+		tasks[name] = t
+	}
+	for name, t := range r.keyedTasks {
+		tasks[name] = t
+	}
+	r.mu.RUnlock()
+
+	out := make(map[string]Stats, len(tasks))
+	for name, t := range tasks {
+		hist := t.History(0)
+		s := Stats{Runs: len(hist)}
+		for i := range hist {
+			if hist[i].Err != nil {
+				s.Errors++
+			}
+			if hist[i].Panic != nil {
+				s.Panics++
+			}
+		}
+		if len(hist) > 0 {
+			last := hist[len(hist)-1]
+			s.Last = &last
+		}
+		out[name] = s
+	}
+	return out
+}
+
+// PublishExpvar registers an expvar.Map under name exposing aggregate
+// run/error/panic counts across every task the Runner manages, computed
+// on demand from Stats so /debug/vars always reflects the current
+// state. It panics if name is already registered, same as expvar.NewMap.
+func (r *Runner) PublishExpvar(name string) *expvar.Map {
+	m := expvar.NewMap(name)
+	m.Set("runs", expvar.Func(func() interface{} { return r.totalStat(func(s Stats) int { return s.Runs }) }))
+	m.Set("errors", expvar.Func(func() interface{} { return r.totalStat(func(s Stats) int { return s.Errors }) }))
+	m.Set("panics", expvar.Func(func() interface{} { return r.totalStat(func(s Stats) int { return s.Panics }) }))
+	return m
+}
+
+func (r *Runner) totalStat(get func(Stats) int) int {
+	total := 0
+	for _, s := range r.Stats() {
+		total += get(s)
+	}
+	return total
+}
@@ -0,0 +1,162 @@
+package gtasks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingObserver struct {
+	mu       sync.Mutex
+	starts   []string
+	finishes []TaskResult
+	panics   []interface{}
+	cancels  []string
+}
+
+func (o *recordingObserver) OnStart(name string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.starts = append(o.starts, name)
+}
+
+func (o *recordingObserver) OnFinish(name string, res TaskResult) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.finishes = append(o.finishes, res)
+}
+
+func (o *recordingObserver) OnCancel(name string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cancels = append(o.cancels, name)
+}
+
+func (o *recordingObserver) OnPanic(name string, p interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.panics = append(o.panics, p)
+}
+
+func TestTaskPanicRecovery(t *testing.T) {
+	obs := &recordingObserver{}
+
+	t1 := NewTask(func(chan bool) {
+		panic("boom")
+	})
+	t1.Observe(obs)
+
+	if err := t1.Run(); err == nil {
+		t.Fatal("expected the recovered panic to surface as an error")
+	}
+
+	last := t1.LastResult()
+	if last == nil || last.Panic != "boom" {
+		t.Fatalf("expected history to record the panic, got %+v", last)
+	}
+
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if len(obs.panics) != 1 || obs.panics[0] != "boom" {
+		t.Fatalf("expected OnPanic to fire once with \"boom\", got %v", obs.panics)
+	}
+}
+
+func TestTaskHistoryAndObserver(t *testing.T) {
+	obs := &recordingObserver{}
+
+	i := 0
+	t1 := NewTask(func(chan bool) {
+		i++
+	})
+	t1.Observe(obs)
+
+	if err := t1.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hist := t1.History(0)
+	if len(hist) != 1 {
+		t.Fatalf("expected 1 history entry, got %d", len(hist))
+	}
+
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	if len(obs.starts) != 1 || len(obs.finishes) != 1 {
+		t.Fatalf("expected one OnStart and one OnFinish, got %d/%d", len(obs.starts), len(obs.finishes))
+	}
+}
+
+func TestTaskContextAttemptsAccumulateUntilSuccess(t *testing.T) {
+	attempt := 0
+	t1 := NewTaskContext(func(ctx context.Context) error {
+		attempt++
+		if attempt < 2 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+
+	t1.WithBackoff(time.Millisecond, time.Millisecond*5, 2)
+	t1.Every(time.Hour)
+	t1.Once()
+	t1.Now()
+
+	if err := t1.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hist := t1.History(0)
+	if len(hist) != 2 {
+		t.Fatalf("expected 2 history entries, got %d", len(hist))
+	}
+	if hist[0].Attempts != 1 || hist[1].Attempts != 2 {
+		t.Fatalf("expected attempts 1 then 2, got %d then %d", hist[0].Attempts, hist[1].Attempts)
+	}
+}
+
+// TestTaskAttemptsWithoutBackoffDoesNotAccumulate guards against
+// Attempts conflating independent Every/Cron firings into a fake retry
+// sequence when no WithBackoff is configured.
+func TestTaskAttemptsWithoutBackoffDoesNotAccumulate(t *testing.T) {
+	t1 := NewTaskContext(func(ctx context.Context) error {
+		return errors.New("always fails")
+	})
+
+	t1.Every(time.Millisecond * 10)
+	go t1.Run()
+	time.Sleep(time.Millisecond * 45)
+	t1.Cancel()
+	t1.Wait()
+
+	hist := t1.History(0)
+	if len(hist) < 2 {
+		t.Fatalf("expected at least 2 history entries, got %d", len(hist))
+	}
+	for _, h := range hist {
+		if h.Attempts != 1 {
+			t.Fatalf("expected every entry to report Attempts 1 without WithBackoff, got %d", h.Attempts)
+		}
+	}
+}
+
+func TestRunnerStats(t *testing.T) {
+	r := New()
+	r.Add("ok", func(chan bool) {}).Once()
+	r.AddContext("fail", func(ctx context.Context) error {
+		return errors.New("nope")
+	}).Once()
+
+	r.Get("ok").Run()
+	r.Get("fail").Run()
+
+	stats := r.Stats()
+	if stats["ok"].Runs != 1 || stats["ok"].Errors != 0 {
+		t.Fatalf("unexpected stats for ok: %+v", stats["ok"])
+	}
+	if stats["fail"].Runs != 1 || stats["fail"].Errors != 1 {
+		t.Fatalf("unexpected stats for fail: %+v", stats["fail"])
+	}
+}
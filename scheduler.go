@@ -0,0 +1,244 @@
+package gtasks
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedSource is the common interface behind the Every/Cron scheduling
+// builders: something that periodically produces times on a channel and
+// can be stopped once, mirroring time.Ticker.
+type schedSource interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Every schedules the task to run repeatedly on a fixed interval d,
+// using an internal ticker that is stopped when the task is canceled.
+// It is equivalent to hand-wiring t.After(time.NewTicker(d).C) plus
+// remembering to stop the ticker on Cancel.
+func (t *Task) Every(d time.Duration) *Task {
+	return t.schedule(newTickerSource(d))
+}
+
+// Cron schedules the task according to spec, a standard five field cron
+// expression ("minute hour day-of-month month day-of-week"), each field
+// accepting *, a value, a-b ranges, a,b,c lists and a */n step. The task
+// is rescheduled to the next matching minute after every run. Cron
+// panics if spec cannot be parsed, since an invalid schedule is a
+// programming error to be caught at startup rather than at run time.
+func (t *Task) Cron(spec string) *Task {
+	sched, err := parseCron(spec)
+	if err != nil {
+		panic("gtasks: invalid cron spec " + strconv.Quote(spec) + ": " + err.Error())
+	}
+	return t.schedule(newCronSource(sched))
+}
+
+// WithJitter adds a random delay in [0, d) before every scheduled
+// firing, smoothing out the thundering herd that comes from many tasks
+// sharing the same interval or cron spec. It must be called after
+// Every/Cron/After has set up the channel to jitter. Like After, its
+// forwarding goroutine exits as soon as the task is canceled, even
+// mid-sleep, instead of blocking forever on a send nobody will read.
+func (t *Task) WithJitter(d time.Duration) *Task {
+	if t.after == nil || d <= 0 {
+		return t
+	}
+	src := t.after
+	done := t.ctx.Done()
+	out := make(chan interface{})
+	go func() {
+		for {
+			select {
+			case v, ok := <-src:
+				if !ok {
+					close(out)
+					return
+				}
+				select {
+				case <-time.After(time.Duration(rand.Int63n(int64(d)))):
+				case <-done:
+					close(out)
+					return
+				}
+				select {
+				case out <- v:
+				case <-done:
+					close(out)
+					return
+				}
+			case <-done:
+				close(out)
+				return
+			}
+		}
+	}()
+	t.after = out
+	return t
+}
+
+// WithBackoff enables retry-on-error semantics for a NewTaskContext
+// task: when f returns a non-nil error, the task is retried after
+// initial, growing by factor on every consecutive failure up to max,
+// instead of waiting for the next Every/Cron firing. The backoff resets
+// to initial as soon as a run succeeds. On the legacy chan bool path f
+// returns no error, so a run there is only retried after a recovered
+// panic.
+func (t *Task) WithBackoff(initial, max time.Duration, factor float64) *Task {
+	t.backoffInitial = initial
+	t.backoffMax = max
+	t.backoffFactor = factor
+	return t
+}
+
+func (t *Task) schedule(src schedSource) *Task {
+	t.schedSources = append(t.schedSources, src)
+	t.After(src.C())
+	return t
+}
+
+// tickerSource adapts *time.Ticker to schedSource.
+type tickerSource struct {
+	t *time.Ticker
+}
+
+func newTickerSource(d time.Duration) *tickerSource {
+	return &tickerSource{t: time.NewTicker(d)}
+}
+
+func (s *tickerSource) C() <-chan time.Time { return s.t.C }
+func (s *tickerSource) Stop()               { s.t.Stop() }
+
+// cronSource computes the next matching time from a cronSchedule,
+// sleeps until it arrives and sends it on c, then repeats - until
+// stopped.
+type cronSource struct {
+	c    chan time.Time
+	stop chan struct{}
+}
+
+func newCronSource(cs *cronSchedule) *cronSource {
+	s := &cronSource{
+		c:    make(chan time.Time),
+		stop: make(chan struct{}),
+	}
+	go s.run(cs)
+	return s
+}
+
+func (s *cronSource) run(cs *cronSchedule) {
+	for {
+		next := cs.next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case now := <-timer.C:
+			select {
+			case s.c <- now:
+			case <-s.stop:
+				return
+			}
+		case <-s.stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+func (s *cronSource) C() <-chan time.Time { return s.c }
+
+func (s *cronSource) Stop() { close(s.stop) }
+
+// cronSchedule is a parsed five field cron expression, stored as one
+// membership set per field.
+type cronSchedule struct {
+	minute [60]bool
+	hour   [24]bool
+	dom    [32]bool
+	month  [13]bool
+	dow    [7]bool
+}
+
+func parseCron(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields (minute hour dom month dow), got %d", len(fields))
+	}
+
+	cs := &cronSchedule{}
+	if err := parseCronField(fields[0], 0, 59, cs.minute[:]); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if err := parseCronField(fields[1], 0, 23, cs.hour[:]); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if err := parseCronField(fields[2], 1, 31, cs.dom[:]); err != nil {
+		return nil, fmt.Errorf("day-of-month: %w", err)
+	}
+	if err := parseCronField(fields[3], 1, 12, cs.month[:]); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if err := parseCronField(fields[4], 0, 6, cs.dow[:]); err != nil {
+		return nil, fmt.Errorf("day-of-week: %w", err)
+	}
+	return cs, nil
+}
+
+func parseCronField(field string, min, max int, set []bool) error {
+	for _, part := range strings.Split(field, ",") {
+		rangePart := part
+		step := 1
+		if idx := strings.IndexByte(part, '/'); idx >= 0 {
+			rangePart = part[:idx]
+			s, err := strconv.Atoi(part[idx+1:])
+			if err != nil || s <= 0 {
+				return fmt.Errorf("invalid step in %q", part)
+			}
+			step = s
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			if idx := strings.IndexByte(rangePart, '-'); idx >= 0 {
+				l, errL := strconv.Atoi(rangePart[:idx])
+				h, errH := strconv.Atoi(rangePart[idx+1:])
+				if errL != nil || errH != nil {
+					return fmt.Errorf("invalid range %q", rangePart)
+				}
+				lo, hi = l, h
+			} else {
+				v, err := strconv.Atoi(rangePart)
+				if err != nil {
+					return fmt.Errorf("invalid value %q", rangePart)
+				}
+				lo, hi = v, v
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return fmt.Errorf("value out of range in %q", part)
+		}
+		for i := lo; i <= hi; i += step {
+			set[i] = true
+		}
+	}
+	return nil
+}
+
+// next returns the first minute-aligned time strictly after from that
+// matches the schedule. The search is capped at slightly over five
+// years' worth of minutes so an unsatisfiable schedule (e.g. day-of-month
+// 31 combined with month 2) returns instead of looping forever.
+func (cs *cronSchedule) next(from time.Time) time.Time {
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	for i := 0; i < 5*366*24*60; i++ {
+		if cs.month[int(t.Month())] && cs.dom[t.Day()] && cs.dow[int(t.Weekday())] &&
+			cs.hour[t.Hour()] && cs.minute[t.Minute()] {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return t
+}
@@ -0,0 +1,106 @@
+package gtasks
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestParseCronEveryMinute(t *testing.T) {
+	cs, err := parseCron("* * * * *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	from := time.Date(2020, 1, 1, 12, 30, 0, 0, time.UTC)
+	next := cs.next(from)
+	want := time.Date(2020, 1, 1, 12, 31, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Fatalf("next = %v, want %v", next, want)
+	}
+}
+
+func TestParseCronFields(t *testing.T) {
+	cs, err := parseCron("0 9 * * 1-5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Wednesday 2020-01-01 08:00 -> next matching slot is 09:00 the same day.
+	from := time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	next := cs.next(from)
+	want := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Fatalf("next = %v, want %v", next, want)
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	if _, err := parseCron("not a cron spec"); err == nil {
+		t.Fatal("expected an error for a malformed spec")
+	}
+}
+
+func TestTaskEvery(t *testing.T) {
+	i := 0
+	t1 := NewTask(func(chan bool) {
+		i++
+	})
+
+	t1.Every(time.Millisecond * 20)
+	go t1.Run()
+
+	time.Sleep(time.Millisecond * 90)
+	t1.Cancel()
+	t1.Wait()
+
+	if i < 2 {
+		t.Fatalf("expected task to have run more than once, ran %d times", i)
+	}
+}
+
+// TestTaskEveryCancelDoesNotLeakBridgeGoroutine guards against a
+// regression where tickerSource.Stop (and cronSource.Stop) never close
+// the channel the After bridge is blocked reading from, leaking that
+// bridge goroutine on every Cancel of an Every/Cron-scheduled task.
+func TestTaskEveryCancelDoesNotLeakBridgeGoroutine(t *testing.T) {
+	runtime.GC()
+	base := runtime.NumGoroutine()
+
+	t1 := NewTask(func(chan bool) {})
+	t1.Every(time.Millisecond * 5)
+	time.Sleep(time.Millisecond * 20)
+	t1.Cancel()
+	time.Sleep(time.Millisecond * 50)
+
+	runtime.GC()
+	if after := runtime.NumGoroutine(); after > base+1 {
+		t.Fatalf("possible goroutine leak: base=%d after=%d", base, after)
+	}
+}
+
+func TestTaskWithBackoff(t *testing.T) {
+	attempts := 0
+	t1 := NewTaskContext(func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+
+	t1.Every(time.Hour) // first run is driven by Now, retries by the backoff
+	t1.Once()
+	t1.WithBackoff(time.Millisecond*10, time.Millisecond*50, 2)
+	t1.Now()
+
+	if err := t1.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
@@ -1,11 +1,27 @@
 package gtasks
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"sync"
 	"time"
 )
 
+// ErrAlreadyStarted is returned by Run when a task has already been
+// started once. Tasks are single-shot with respect to Run: re-running a
+// task requires creating a new one.
+var ErrAlreadyStarted = errors.New("gtasks: task already started")
+
+// ErrAlreadyStopped is returned by Cancel when a task has already been
+// canceled.
+var ErrAlreadyStopped = errors.New("gtasks: task already stopped")
+
+// ErrNotStarted is returned by Wait when called on a task that hasn't
+// been run yet, since there is nothing to wait for.
+var ErrNotStarted = errors.New("gtasks: task not started")
+
 // New returns a new *runner to register tasks
 func New() *Runner {
 	return &Runner{
@@ -18,15 +34,52 @@ func New() *Runner {
 type Runner struct {
 	tasks map[string]*Task
 	mu    sync.RWMutex
+
+	sem        chan struct{}
+	keyedTasks map[string]*Task
+	keyed      map[string]*keyedCall
+	edges      map[string][]string
+	observers  []Observer
 }
 
-// Run will start the tasks
-func (r *Runner) Run() {
+// Run will start the tasks. It returns an error combining every
+// ErrAlreadyStarted it encountered; tasks that had not been started yet
+// are still launched. If SetMaxConcurrent has been called, at most that
+// many tasks run at once; the rest wait for a free slot.
+func (r *Runner) Run() error {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	sem := r.sem
+	var errs []error
 	for _, task := range r.tasks {
-		go task.Run()
+		task := task
+		if task.hasStarted() {
+			errs = append(errs, ErrAlreadyStarted)
+			continue
+		}
+		go func() {
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
+			task.Run()
+		}()
+	}
+	return joinErrors(errs)
+}
+
+// SetMaxConcurrent caps how many tasks Run will execute at the same
+// time to n. A value <= 0 restores the default of unbounded concurrency.
+// Tasks beyond the cap queue on a semaphore and start as soon as a slot
+// frees up; Run itself still returns immediately regardless of the cap.
+func (r *Runner) SetMaxConcurrent(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n <= 0 {
+		r.sem = nil
+		return
 	}
+	r.sem = make(chan struct{}, n)
 }
 
 // Cancel stops a single task from executing: see Task.Cancel
@@ -36,6 +89,10 @@ func (r *Runner) Cancel(name string) {
 		t.Cancel()
 		delete(r.tasks, name)
 	}
+	if t, ok := r.keyedTasks[name]; ok {
+		t.Cancel()
+		delete(r.keyedTasks, name)
+	}
 	r.mu.Unlock()
 }
 
@@ -46,16 +103,24 @@ func (r *Runner) CancelAll() {
 		t.Cancel()
 		delete(r.tasks, name)
 	}
+	for name, t := range r.keyedTasks {
+		t.Cancel()
+		delete(r.keyedTasks, name)
+	}
 	r.mu.Unlock()
 }
 
-// Get returns a task by name
+// Get returns a task by name, whether it was registered with Add/
+// AddContext or AddKeyed.
 func (r *Runner) Get(name string) *Task {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	if t, ok := r.tasks[name]; ok {
 		return t
 	}
+	if t, ok := r.keyedTasks[name]; ok {
+		return t
+	}
 	return nil
 }
 
@@ -69,8 +134,22 @@ func (r *Runner) All() map[string]*Task {
 // Add adds a new task to a runner
 func (r *Runner) Add(name string, f func(chan bool)) *Task {
 	t := NewTask(f)
+	t.name = name
+	r.mu.Lock()
+	r.tasks[name] = t
+	r.attach(t)
+	r.mu.Unlock()
+	return t
+}
+
+// AddContext adds a new context-aware task to a runner. See NewTaskContext
+// for how f is run and canceled.
+func (r *Runner) AddContext(name string, f func(ctx context.Context) error) *Task {
+	t := NewTaskContext(f)
+	t.name = name
 	r.mu.Lock()
 	r.tasks[name] = t
+	r.attach(t)
 	r.mu.Unlock()
 	return t
 }
@@ -78,10 +157,26 @@ func (r *Runner) Add(name string, f func(chan bool)) *Task {
 // NewTask creates a new taks an inits the needed variables
 func NewTask(f func(chan bool)) *Task {
 	t := &Task{
-		f:          f,
-		cancelchan: make(chan bool),
-		listeners:  make([]chan interface{}, 0),
+		f:         f,
+		listeners: make([]chan Result, 0),
 	}
+	t.init()
+	return t
+}
+
+// NewTaskContext creates a new Task whose function receives a
+// context.Context instead of the legacy chan bool. The context is
+// canceled when Cancel is called, letting f propagate deadlines, values
+// and the cancellation cause via ctx.Err(), and its returned error is
+// made available through Wait. The chan bool based Add/NewTask path
+// keeps working unchanged; this is an additional, not a replacement, way
+// to register a task.
+func NewTaskContext(f func(ctx context.Context) error) *Task {
+	t := &Task{
+		ctxFunc:   f,
+		listeners: make([]chan Result, 0),
+	}
+	t.init()
 	return t
 }
 
@@ -90,26 +185,97 @@ func NewTask(f func(chan bool)) *Task {
 // that accepts a chan bool which will be closed
 // if the task is canceled
 type Task struct {
+	name       string
 	f          func(chan bool)
+	ctxFunc    func(ctx context.Context) error
+	valueFunc  func(ctx context.Context) (interface{}, error)
 	cancelchan chan bool
+	ctx        context.Context
+	cancel     context.CancelFunc
 	after      chan interface{}
-	listeners  []chan interface{}
+	listeners  []chan Result
 	once       bool
 	runStart   time.Time
 	running    bool
+
+	mu        sync.Mutex
+	started   bool
+	stopped   bool
+	done      chan struct{}
+	err       error
+	attempts  int
+	history   []TaskResult
+	observers []Observer
+
+	schedSources  []schedSource
+	triggerPolicy TriggerPolicy
+
+	backoffInitial time.Duration
+	backoffMax     time.Duration
+	backoffFactor  float64
+	backoffCur     time.Duration
+}
+
+// init lazily fills in the fields NewTask/NewTaskContext normally set up,
+// so a Task built as a bare struct literal (as the older tests do) keeps
+// working without a constructor call.
+func (t *Task) init() {
+	if t.cancelchan == nil {
+		t.cancelchan = make(chan bool)
+	}
+	if t.ctx == nil {
+		t.ctx, t.cancel = context.WithCancel(context.Background())
+	}
+	if t.done == nil {
+		t.done = make(chan struct{})
+	}
+}
+
+func (t *Task) hasStarted() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.started
 }
 
-// Cancel stops a task. Executing functions
-// should monitor if the cancelchan is closed
-func (t *Task) Cancel() {
+// Cancel stops a task. Executing functions should monitor if the
+// cancelchan is closed, or select on the ctx passed to a
+// NewTaskContext function. It returns ErrAlreadyStopped if the task was
+// already canceled.
+func (t *Task) Cancel() error {
+	t.init()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return ErrAlreadyStopped
+	}
+	t.stopped = true
 	close(t.cancelchan)
+	t.cancel()
+	for _, src := range t.schedSources {
+		src.Stop()
+	}
+	for _, o := range t.observers {
+		o.OnCancel(t.name)
+	}
+	return nil
 }
 
 // After sets the channel which will start the task.
 // If it is not set the task will run immediately
 // when calling Run and return after that
 func (t *Task) After(c interface{}) *Task {
-	t.after = Wrap(c)
+	t.init()
+	t.after = wrapChan(c, t.triggerPolicy, t.ctx.Done())
+	return t
+}
+
+// WithTriggerPolicy controls what the After bridge does with a new
+// value once it's been set up (via After, Every, Cron, ...) when the
+// task's Run loop isn't currently parked waiting for it: see
+// TriggerPolicy. It must be called before After/Every/Cron for the
+// policy to take effect, since that's when the bridge goroutine starts.
+func (t *Task) WithTriggerPolicy(p TriggerPolicy) *Task {
+	t.triggerPolicy = p
 	return t
 }
 
@@ -131,53 +297,213 @@ func (t *Task) Once() *Task {
 	return t
 }
 
-// Subscribe returns a channel that can be used in the After func.
-// This way tasks can be depedent on each other.
-func (t *Task) Subscribe() chan interface{} {
-	c := make(chan interface{}, 1) // always make room for 1 item to be non-blocking
+// Subscribe returns a channel that receives the Result of every run of
+// this task, and can also be used in the After func so that tasks can
+// depend on each other. The channel buffers one Result; a Result
+// published while that slot is still full is dropped for this listener.
+func (t *Task) Subscribe() chan Result {
+	c := make(chan Result, 1) // always make room for 1 item to be non-blocking
 	t.listeners = append(t.listeners, c)
 	return c
 }
 
-// Run will run a task
-func (t *Task) Run() {
+// Run will run a task, blocking until it finishes, is canceled, or (when
+// After has been set) its channel is exhausted. It returns
+// ErrAlreadyStarted if the task was already run, ErrAlreadyStopped if it
+// was canceled before it could start, nil if it was canceled while
+// waiting on its After channel, and otherwise the error from the last
+// execution of f (for the legacy chan bool path, non-nil only if f
+// panicked).
+func (t *Task) Run() error {
+	t.init()
+
+	t.mu.Lock()
+	if t.started {
+		t.mu.Unlock()
+		return ErrAlreadyStarted
+	}
+	if t.stopped {
+		t.mu.Unlock()
+		return ErrAlreadyStopped
+	}
+	t.started = true
+	t.mu.Unlock()
+
+	defer close(t.done)
+
 	if t.after == nil {
 		t.exec()
-		return
+		return t.err
 	}
 
 	for {
 		select {
-		case _, opened := <-t.cancelchan:
-			if opened == false {
-				return
-			}
+		case <-t.ctx.Done():
+			return nil
 		case <-t.after:
 			t.exec()
+			t.retryWithBackoff()
 			if t.once {
-				return
+				return t.err
 			}
 		}
 	}
 }
 
+// retryWithBackoff keeps re-running the task, without waiting for the
+// next After trigger, while it keeps returning an error and WithBackoff
+// has been configured - waiting backoffCur (growing by backoffFactor up
+// to backoffMax on every consecutive failure) between attempts. It
+// returns as soon as a run succeeds or the task is canceled, resetting
+// the backoff for the next round of failures.
+func (t *Task) retryWithBackoff() {
+	if t.backoffInitial <= 0 {
+		return
+	}
+	for t.err != nil {
+		if t.backoffCur <= 0 {
+			t.backoffCur = t.backoffInitial
+		}
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-time.After(t.backoffCur):
+		}
+		t.exec()
+		next := time.Duration(float64(t.backoffCur) * t.backoffFactor)
+		if t.backoffMax > 0 && next > t.backoffMax {
+			next = t.backoffMax
+		}
+		t.backoffCur = next
+	}
+	t.backoffCur = 0
+}
+
+// Wait blocks until the task has finished (or was canceled while Run was
+// waiting on its After channel) and returns the error from its last
+// run, if any.
+// ErrNotStarted is returned immediately if the task was never run.
+func (t *Task) Wait() error {
+	t.mu.Lock()
+	started := t.started
+	t.mu.Unlock()
+	if !started {
+		return ErrNotStarted
+	}
+	<-t.done
+	return t.err
+}
+
 func (t *Task) exec() {
+	res := t.invoke()
+	t.publish(res)
+}
+
+// invoke runs f/ctxFunc/valueFunc once, recovering a panic into an
+// error instead of letting it crash the process, records a TaskResult
+// in the task's history and notifies any Observers. It does not publish
+// to Subscribe()rs, since callers such as RunKeyed need to coalesce that
+// separately.
+func (t *Task) invoke() Result {
 	t.running = true
 	t.runStart = time.Now()
-	t.f(t.cancelchan)
+
+	for _, o := range t.observers {
+		o.OnStart(t.name)
+	}
+
+	var value interface{}
+	var panicked interface{}
+	func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked = p
+				t.err = fmt.Errorf("gtasks: task %q panicked: %v", t.name, p)
+			}
+		}()
+		switch {
+		case t.valueFunc != nil:
+			value, t.err = t.valueFunc(t.ctx)
+		case t.ctxFunc != nil:
+			t.err = t.ctxFunc(t.ctx)
+		default:
+			t.err = nil
+			t.f(t.cancelchan)
+		}
+	}()
+
 	t.running = false
+
+	t.mu.Lock()
+	// Attempts only accumulates across a WithBackoff retry sequence; a
+	// task with no backoff configured reports 1 for every independent
+	// execution (e.g. separate Every/Cron firings), not a running total.
+	if t.backoffInitial > 0 {
+		t.attempts++
+	} else {
+		t.attempts = 1
+	}
+	tr := TaskResult{Start: t.runStart, End: time.Now(), Err: t.err, Panic: panicked, Attempts: t.attempts}
+	t.history = append(t.history, tr)
+	if t.err == nil {
+		t.attempts = 0
+	}
+	t.mu.Unlock()
+
+	for _, o := range t.observers {
+		o.OnFinish(t.name, tr)
+		if panicked != nil {
+			o.OnPanic(t.name, panicked)
+		}
+	}
+
+	return Result{Value: value, Err: t.err}
+}
+
+// publish fans res out to every Subscribe()r, dropping it for any
+// listener that isn't ready to receive so a slow subscriber can never
+// stall the task.
+func (t *Task) publish(res Result) {
 	for _, l := range t.listeners {
 		select {
-		case l <- true:
+		case l <- res:
 		default:
 		}
 	}
 }
 
+// multiError joins several errors into one, mirroring the handful of
+// independent failures Runner.Run can produce without pulling in a
+// dependency.
+type multiError []error
+
+func (m multiError) Error() string {
+	s := ""
+	for i, err := range m {
+		if i > 0 {
+			s += "; "
+		}
+		s += err.Error()
+	}
+	return s
+}
+
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return multiError(errs)
+}
+
 // Wrap is copied from https://github.com/eapache/channels
 // Wrap takes any readable channel type (chan or <-chan but not chan<-) and
 // exposes it as a SimpleOutChannel for easy integration with existing channel sources.
 // It panics if the input is not a readable channel.
+//
+// Wrap keeps its original unbuffered, drop-on-no-receiver behavior for
+// backward compatibility with any caller using it directly. Task.After
+// no longer uses it internally; see wrapChan and WithTriggerPolicy for
+// the bounded-buffer bridge with a configurable drop/block policy.
 func Wrap(ch interface{}) chan interface{} {
 	t := reflect.TypeOf(ch)
 	if t.Kind() != reflect.Chan || t.ChanDir()&reflect.RecvDir == 0 {
@@ -1,6 +1,7 @@
 package gtasks
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -114,6 +115,69 @@ func TestRunner(t *testing.T) {
 	}
 }
 
+func TestTaskContext(t *testing.T) {
+	i := 0
+	t1 := NewTaskContext(func(ctx context.Context) error {
+		i++
+		return nil
+	})
+
+	if err := t1.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if i != 1 {
+		t.Fatal("i should be 1")
+	}
+
+	if err := t1.Wait(); err != nil {
+		t.Fatalf("unexpected error from Wait: %v", err)
+	}
+}
+
+func TestTaskRunLifecycleErrors(t *testing.T) {
+	t1 := NewTask(func(chan bool) {})
+
+	if err := t1.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := t1.Run(); err != ErrAlreadyStarted {
+		t.Fatalf("expected ErrAlreadyStarted, got %v", err)
+	}
+
+	t2 := NewTask(func(chan bool) {})
+	if err := t2.Cancel(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := t2.Cancel(); err != ErrAlreadyStopped {
+		t.Fatalf("expected ErrAlreadyStopped, got %v", err)
+	}
+	if err := t2.Run(); err != ErrAlreadyStopped {
+		t.Fatalf("expected ErrAlreadyStopped, got %v", err)
+	}
+
+	t3 := NewTask(func(chan bool) {})
+	if err := t3.Wait(); err != ErrNotStarted {
+		t.Fatalf("expected ErrNotStarted, got %v", err)
+	}
+}
+
+func TestTaskContextCancel(t *testing.T) {
+	t1 := NewTaskContext(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	go t1.Run()
+	time.Sleep(time.Millisecond * 50)
+	t1.Cancel()
+
+	if err := t1.Wait(); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
 func ExampleTaskAfter() {
 	tick := time.After(time.Millisecond * 100)
 
@@ -0,0 +1,142 @@
+package gtasks
+
+import "reflect"
+
+// TriggerPolicy controls what the After bridge does with a new value
+// when the task's Run loop isn't currently parked in its select waiting
+// for one - the old Wrap-based bridge always silently dropped it, which
+// could lose triggers under load.
+type TriggerPolicy int
+
+const (
+	// Block waits until the task is ready to receive the trigger,
+	// applying backpressure to the source channel. This is the default,
+	// since it's the only policy that never drops a trigger.
+	Block TriggerPolicy = iota
+	// DropOldest discards whatever trigger is currently buffered to make
+	// room for the newest one.
+	DropOldest
+	// DropNewest discards the incoming trigger if one is already
+	// buffered, keeping whatever was waiting first.
+	DropNewest
+)
+
+// wrapChan is the policy-aware replacement for Wrap that After uses
+// internally: same reflection-based bridging of any readable channel
+// type, but backed by a one-deep buffer whose overflow behavior is
+// governed by policy instead of being unconditionally dropped, and whose
+// forwarding goroutine exits as soon as done is closed instead of only
+// on ch closing - ch may be a ticker/cron source whose own Stop() never
+// closes it, and done (the task's ctx.Done()) is what Cancel actually
+// signals. A bidirectional chan interface{} is returned unchanged, with
+// no buffer, policy or forwarding goroutine in between.
+func wrapChan(ch interface{}, policy TriggerPolicy, done <-chan struct{}) chan interface{} {
+	rt := reflect.TypeOf(ch)
+	if rt.Kind() != reflect.Chan || rt.ChanDir()&reflect.RecvDir == 0 {
+		panic("gtasks: input to After must be a readable channel")
+	}
+
+	if c, ok := ch.(chan interface{}); ok {
+		return c
+	}
+
+	out := make(chan interface{}, 1)
+	go func() {
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)},
+		}
+		for {
+			chosen, recv, ok := reflect.Select(cases)
+			if chosen == 1 || !ok {
+				close(out)
+				return
+			}
+			sendTrigger(out, recv.Interface(), policy, done)
+		}
+	}()
+	return out
+}
+
+// sendTrigger delivers v on out according to policy. The Block case also
+// gives up as soon as done is closed, so a canceled task's forwarding
+// goroutine can't be left blocked forever on a send nobody will ever
+// read.
+func sendTrigger(out chan interface{}, v interface{}, policy TriggerPolicy, done <-chan struct{}) {
+	switch policy {
+	case DropNewest:
+		select {
+		case out <- v:
+		default:
+		}
+	case DropOldest:
+		for {
+			select {
+			case out <- v:
+				return
+			default:
+			}
+			select {
+			case <-out:
+			default:
+			}
+		}
+	default: // Block
+		select {
+		case out <- v:
+		case <-done:
+		}
+	}
+}
+
+// SubscribeT is the generic counterpart to Task.Subscribe: it returns a
+// channel carrying the upstream task's Result.Value already asserted to
+// T, for tasks registered with a value-producing function (see
+// Runner.AddKeyed) whose Value is known to be a T. A Result with a
+// non-nil Err, or whose Value isn't a T, is dropped rather than
+// delivered, since there's no zero-cost way to report that on a plain
+// `chan T`.
+func SubscribeT[T any](t *Task) chan T {
+	src := t.Subscribe()
+	out := make(chan T, 1)
+	go func() {
+		for res := range src {
+			if res.Err != nil {
+				continue
+			}
+			if v, ok := res.Value.(T); ok {
+				out <- v
+			}
+		}
+	}()
+	return out
+}
+
+// AfterT is the generic counterpart to Task.After: it schedules the
+// task to start whenever the typed channel c delivers a value. Unlike
+// After, it never goes through reflection, since the compiler already
+// knows c is a readable channel of T. Like After, its forwarding
+// goroutine exits as soon as the task is canceled, even if c is never
+// closed.
+func AfterT[T any](t *Task, c <-chan T) *Task {
+	t.init()
+	out := make(chan interface{}, 1)
+	done := t.ctx.Done()
+	go func() {
+		for {
+			select {
+			case v, ok := <-c:
+				if !ok {
+					close(out)
+					return
+				}
+				sendTrigger(out, v, t.triggerPolicy, done)
+			case <-done:
+				close(out)
+				return
+			}
+		}
+	}()
+	t.after = out
+	return t
+}
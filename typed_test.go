@@ -0,0 +1,72 @@
+package gtasks
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubscribeTDeliversTypedValue(t *testing.T) {
+	r := New()
+	r.AddKeyed("greeting", func(ctx context.Context) (interface{}, error) {
+		return "hello", nil
+	})
+
+	greetings := SubscribeT[string](r.Get("greeting"))
+
+	r.RunKeyed("greeting")
+
+	select {
+	case v := <-greetings:
+		if v != "hello" {
+			t.Fatalf("expected %q, got %q", "hello", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the typed value")
+	}
+}
+
+func TestAfterTTriggersTask(t *testing.T) {
+	i := 0
+	t1 := NewTask(func(chan bool) {
+		i++
+	})
+
+	trigger := make(chan int, 1)
+	AfterT(t1, (<-chan int)(trigger))
+	t1.Once()
+
+	trigger <- 1
+
+	if err := t1.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if i != 1 {
+		t.Fatal("i should be 1")
+	}
+}
+
+func TestTriggerPolicyDropOldest(t *testing.T) {
+	src := make(chan int)
+	t1 := NewTask(func(chan bool) {})
+	t1.WithTriggerPolicy(DropOldest)
+	AfterT(t1, (<-chan int)(src))
+
+	// Flood triggers faster than anything drains t1.after; DropOldest
+	// must keep the bridge's buffer at exactly one pending trigger
+	// rather than blocking the sender.
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			src <- i
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("DropOldest should never block the sender")
+	}
+}